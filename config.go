@@ -0,0 +1,81 @@
+package auconfig
+
+import (
+	"sync"
+
+	auconfigapi "github.com/StephanHCB/go-autumn-config-api"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Config holds one isolated configuration instance: its own flag set, its own viper instance,
+// and everything Setup()/Load() need to track along the way. Use NewConfig to create one, or
+// use the package-level functions, which operate on a shared default instance for backward
+// compatibility.
+type Config struct {
+	FlagSet *pflag.FlagSet
+	Viper   *viper.Viper
+
+	// mu guards every access to Viper once Watch() or WatchRemote() may be running in the
+	// background, so a reload/rollback can never race with a concurrent Get/Unmarshal call.
+	mu sync.RWMutex
+
+	configPath  string
+	secretsPath string
+
+	failFunction auconfigapi.ConfigFailFunc
+	warnFunction auconfigapi.ConfigWarnFunc
+
+	configItems               []auconfigapi.ConfigItem
+	configItemKeysWithNoFlags map[string]bool
+	flagRegistrars            map[string]FlagRegistrar
+
+	// watchedFiles lists every config/secrets/profile file path performLoad has read from or
+	// attempted to read from, so Watch() knows exactly what to watch for changes.
+	watchedFiles []string
+
+	remoteConfigItems   []RemoteConfigItem
+	remoteProviders     []RemoteProvider
+	remoteViperInstance *viper.Viper
+
+	globalChangeListeners []ConfigChangeFunc
+	keyedChangeListeners  map[string][]ConfigChangeFunc
+}
+
+// NewConfig creates a new, isolated Config backed by the given flag set and viper instance.
+//
+// This lets libraries and tests instantiate multiple independent configurations in one
+// process, and lets consumers inject their own pflag.FlagSet, e.g. one built with cobra,
+// instead of fighting the shared pflag.CommandLine.
+func NewConfig(flagSet *pflag.FlagSet, v *viper.Viper) *Config {
+	return &Config{
+		FlagSet: flagSet,
+		Viper:   v,
+
+		failFunction: fail,
+		warnFunction: warn,
+
+		configItemKeysWithNoFlags: map[string]bool{},
+		flagRegistrars:            map[string]FlagRegistrar{},
+
+		keyedChangeListeners: map[string][]ConfigChangeFunc{},
+	}
+}
+
+// defaultConfig is the instance backing the package-level functions, for backward compatibility
+// with code written before multiple Config instances were supported.
+var defaultConfig = NewConfig(pflag.CommandLine, viper.GetViper())
+
+// Get returns the fully resolved value (flag > env > file > default) for key.
+func (c *Config) Get(key string) interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.Viper.Get(key)
+}
+
+// Get returns the fully resolved value (flag > env > file > default) for key, using the
+// default Config instance.
+func Get(key string) interface{} {
+	return defaultConfig.Get(key)
+}
@@ -0,0 +1,281 @@
+package auconfig
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	auconfigapi "github.com/StephanHCB/go-autumn-config-api"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// flagTypeCase exercises one configItem data type end-to-end through all four precedence
+// layers: default, config file, env var and flag, each one overriding the previous.
+type flagTypeCase struct {
+	name string
+	item auconfigapi.ConfigItem
+
+	fileContents string
+	envName      string
+	envValue     string
+	flagArg      string
+
+	expectDefault func(*testing.T, *Config)
+	expectFile    func(*testing.T, *Config)
+	expectEnv     func(*testing.T, *Config)
+	expectFlag    func(*testing.T, *Config)
+}
+
+func newFlagTestConfig(t *testing.T, item auconfigapi.ConfigItem, configDir string) *Config {
+	t.Helper()
+
+	c := NewConfig(pflag.NewFlagSet("test", pflag.ContinueOnError), viper.New())
+	c.configItems = []auconfigapi.ConfigItem{item}
+	c.failFunction = func(err error) { t.Fatalf("unexpected fail: %s", err) }
+	c.warnFunction = func(message string) { t.Log(message) }
+
+	c.initializeFlags(configDir, "")
+	return c
+}
+
+func (tc flagTypeCase) run(t *testing.T) {
+	t.Run(tc.name, func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			c := newFlagTestConfig(t, tc.item, "")
+			if err := c.FlagSet.Parse(nil); err != nil {
+				t.Fatalf("could not parse flags: %s", err)
+			}
+			c.setupDefaults()
+			c.setupEnv()
+			c.setupFlags()
+			tc.expectDefault(t, c)
+		})
+
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(tc.fileContents), 0644); err != nil {
+			t.Fatalf("could not write config.yaml: %s", err)
+		}
+
+		t.Run("file", func(t *testing.T) {
+			c := newFlagTestConfig(t, tc.item, dir)
+			if err := c.FlagSet.Parse(nil); err != nil {
+				t.Fatalf("could not parse flags: %s", err)
+			}
+			c.setupDefaults()
+			c.setupEnv()
+			c.setupFlags()
+			c.performLoad()
+			tc.expectFile(t, c)
+		})
+
+		t.Run("env", func(t *testing.T) {
+			os.Setenv(tc.envName, tc.envValue)
+			defer os.Unsetenv(tc.envName)
+
+			c := newFlagTestConfig(t, tc.item, dir)
+			if err := c.FlagSet.Parse(nil); err != nil {
+				t.Fatalf("could not parse flags: %s", err)
+			}
+			c.setupDefaults()
+			c.setupEnv()
+			c.setupFlags()
+			c.performLoad()
+			tc.expectEnv(t, c)
+		})
+
+		t.Run("flag", func(t *testing.T) {
+			os.Setenv(tc.envName, tc.envValue)
+			defer os.Unsetenv(tc.envName)
+
+			c := newFlagTestConfig(t, tc.item, dir)
+			if err := c.FlagSet.Parse([]string{tc.flagArg}); err != nil {
+				t.Fatalf("could not parse flags: %s", err)
+			}
+			c.setupDefaults()
+			c.setupEnv()
+			c.setupFlags()
+			c.performLoad()
+			tc.expectFlag(t, c)
+		})
+	})
+}
+
+func TestAdditionalFlagTypesEndToEnd(t *testing.T) {
+	cases := []flagTypeCase{
+		{
+			name:         "duration",
+			item:         auconfigapi.ConfigItem{Key: "some-duration", Default: 5 * time.Second, Validate: auconfigapi.ConfigNeedsNoValidation},
+			fileContents: "some-duration: 10s\n",
+			envName:      "CONFIG_SOME_DURATION",
+			envValue:     "20s",
+			flagArg:      "--some-duration=30s",
+			expectDefault: func(t *testing.T, c *Config) {
+				assertDuration(t, c, "some-duration", 5*time.Second)
+			},
+			expectFile: func(t *testing.T, c *Config) {
+				assertDuration(t, c, "some-duration", 10*time.Second)
+			},
+			expectEnv: func(t *testing.T, c *Config) {
+				assertDuration(t, c, "some-duration", 20*time.Second)
+			},
+			expectFlag: func(t *testing.T, c *Config) {
+				assertDuration(t, c, "some-duration", 30*time.Second)
+			},
+		},
+		{
+			name:         "float32",
+			item:         auconfigapi.ConfigItem{Key: "some-float32", Default: float32(1.5), Validate: auconfigapi.ConfigNeedsNoValidation},
+			fileContents: "some-float32: 2.5\n",
+			envName:      "CONFIG_SOME_FLOAT32",
+			envValue:     "3.5",
+			flagArg:      "--some-float32=4.5",
+			expectDefault: func(t *testing.T, c *Config) {
+				assertFloat(t, c, "some-float32", 1.5)
+			},
+			expectFile: func(t *testing.T, c *Config) {
+				assertFloat(t, c, "some-float32", 2.5)
+			},
+			expectEnv: func(t *testing.T, c *Config) {
+				assertFloat(t, c, "some-float32", 3.5)
+			},
+			expectFlag: func(t *testing.T, c *Config) {
+				assertFloat(t, c, "some-float32", 4.5)
+			},
+		},
+		{
+			name:         "float64",
+			item:         auconfigapi.ConfigItem{Key: "some-float64", Default: 1.1, Validate: auconfigapi.ConfigNeedsNoValidation},
+			fileContents: "some-float64: 2.2\n",
+			envName:      "CONFIG_SOME_FLOAT64",
+			envValue:     "3.3",
+			flagArg:      "--some-float64=4.4",
+			expectDefault: func(t *testing.T, c *Config) {
+				assertFloat(t, c, "some-float64", 1.1)
+			},
+			expectFile: func(t *testing.T, c *Config) {
+				assertFloat(t, c, "some-float64", 2.2)
+			},
+			expectEnv: func(t *testing.T, c *Config) {
+				assertFloat(t, c, "some-float64", 3.3)
+			},
+			expectFlag: func(t *testing.T, c *Config) {
+				assertFloat(t, c, "some-float64", 4.4)
+			},
+		},
+		{
+			name:         "ip",
+			item:         auconfigapi.ConfigItem{Key: "some-ip", Default: net.ParseIP("10.0.0.1"), Validate: auconfigapi.ConfigNeedsNoValidation},
+			fileContents: "some-ip: 10.0.0.2\n",
+			envName:      "CONFIG_SOME_IP",
+			envValue:     "10.0.0.3",
+			flagArg:      "--some-ip=10.0.0.4",
+			expectDefault: func(t *testing.T, c *Config) {
+				assertIP(t, c, "some-ip", "10.0.0.1")
+			},
+			expectFile: func(t *testing.T, c *Config) {
+				assertIP(t, c, "some-ip", "10.0.0.2")
+			},
+			expectEnv: func(t *testing.T, c *Config) {
+				assertIP(t, c, "some-ip", "10.0.0.3")
+			},
+			expectFlag: func(t *testing.T, c *Config) {
+				assertIP(t, c, "some-ip", "10.0.0.4")
+			},
+		},
+		{
+			name:         "intSlice",
+			item:         auconfigapi.ConfigItem{Key: "some-ints", Default: []int{1, 2}, Validate: auconfigapi.ConfigNeedsNoValidation},
+			fileContents: "some-ints:\n  - 3\n  - 4\n",
+			envName:      "CONFIG_SOME_INTS",
+			envValue:     "5,6",
+			flagArg:      "--some-ints=7,8",
+			expectDefault: func(t *testing.T, c *Config) {
+				assertIntSlice(t, c, "some-ints", []int{1, 2})
+			},
+			expectFile: func(t *testing.T, c *Config) {
+				assertIntSlice(t, c, "some-ints", []int{3, 4})
+			},
+			expectEnv: func(t *testing.T, c *Config) {
+				assertIntSlice(t, c, "some-ints", []int{5, 6})
+			},
+			expectFlag: func(t *testing.T, c *Config) {
+				assertIntSlice(t, c, "some-ints", []int{7, 8})
+			},
+		},
+		{
+			name:         "stringToString",
+			item:         auconfigapi.ConfigItem{Key: "some-map", Default: map[string]string{"a": "1"}, Validate: auconfigapi.ConfigNeedsNoValidation},
+			fileContents: "some-map:\n  b: \"2\"\n",
+			envName:      "CONFIG_SOME_MAP",
+			envValue:     "c=3",
+			flagArg:      "--some-map=d=4",
+			expectDefault: func(t *testing.T, c *Config) {
+				assertStringMap(t, c, "some-map", map[string]string{"a": "1"})
+			},
+			expectFile: func(t *testing.T, c *Config) {
+				assertStringMap(t, c, "some-map", map[string]string{"b": "2"})
+			},
+			expectEnv: func(t *testing.T, c *Config) {
+				assertStringMap(t, c, "some-map", map[string]string{"c": "3"})
+			},
+			expectFlag: func(t *testing.T, c *Config) {
+				assertStringMap(t, c, "some-map", map[string]string{"d": "4"})
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc.run(t)
+	}
+}
+
+func assertDuration(t *testing.T, c *Config, key string, want time.Duration) {
+	t.Helper()
+	if got := c.Viper.GetDuration(key); got != want {
+		t.Errorf("%s: expected %s, got %s", key, want, got)
+	}
+}
+
+func assertFloat(t *testing.T, c *Config, key string, want float64) {
+	t.Helper()
+	if got := c.Viper.GetFloat64(key); got != want {
+		t.Errorf("%s: expected %v, got %v", key, want, got)
+	}
+}
+
+func assertIP(t *testing.T, c *Config, key string, want string) {
+	t.Helper()
+	got := net.ParseIP(c.Viper.GetString(key))
+	if got == nil || !got.Equal(net.ParseIP(want)) {
+		t.Errorf("%s: expected %s, got %s", key, want, c.Viper.GetString(key))
+	}
+}
+
+func assertIntSlice(t *testing.T, c *Config, key string, want []int) {
+	t.Helper()
+	got := c.Viper.GetIntSlice(key)
+	if len(got) != len(want) {
+		t.Fatalf("%s: expected %v, got %v", key, want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s: expected %v, got %v", key, want, got)
+		}
+	}
+}
+
+func assertStringMap(t *testing.T, c *Config, key string, want map[string]string) {
+	t.Helper()
+	got := c.Viper.GetStringMapString(key)
+	if len(got) != len(want) {
+		t.Fatalf("%s: expected %v, got %v", key, want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: expected %v, got %v", key, want, got)
+		}
+	}
+}
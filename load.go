@@ -0,0 +1,73 @@
+package auconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+const configFileBaseName = "config"
+const secretsFileBaseName = "secrets"
+
+// performLoad reads the base config file from configPath, then merges in the base secrets
+// file from secretsPath, if one was configured, and finally layers in any active profiles.
+// Values read from these files override defaults, but env vars and flags still take
+// precedence over them, because viper resolves every key through its layers on each lookup.
+func (c *Config) performLoad() {
+	c.loadConfigFile(configFileBaseName, c.configPath, true)
+	if c.secretsPath != "" {
+		c.loadConfigFile(secretsFileBaseName, c.secretsPath, false)
+	}
+	c.loadProfiles()
+}
+
+// loadProfiles implements Spring-Boot-style profile layering: for each profile named in the
+// resolved `profiles` list, config-<profile>.yaml and secrets-<profile>.yaml are merged in on
+// top of the base files, in the order the profiles are listed, so a later profile overrides an
+// earlier one, and any profile-specific file overrides the base config regardless of profile
+// order. A missing profile file is only a warning, since a profile need not supply its own
+// config or secrets. Like the base files, profiles still lose out to env vars and flags.
+func (c *Config) loadProfiles() {
+	for _, profile := range c.Viper.GetStringSlice(ConfigItemProfile.Key) {
+		c.loadConfigFile(configFileBaseName+"-"+profile, c.configPath, false)
+		if c.secretsPath != "" {
+			c.loadConfigFile(secretsFileBaseName+"-"+profile, c.secretsPath, false)
+		}
+	}
+}
+
+// loadConfigFile reads (or merges) baseName+".yaml" found in dirPath into viper. A missing
+// file is only a warning, because every value can also come from defaults, env vars or flags.
+// The file path is tracked in c.watchedFiles regardless of whether it currently exists, so
+// Watch() can pick up the file later too, e.g. if it is created after the process has started.
+func (c *Config) loadConfigFile(baseName string, dirPath string, isPrimary bool) {
+	path := filepath.Join(dirPath, baseName+".yaml")
+	c.trackWatchedFile(path)
+	c.Viper.SetConfigFile(path)
+
+	var err error
+	if isPrimary {
+		err = c.Viper.ReadInConfig()
+	} else {
+		err = c.Viper.MergeInConfig()
+	}
+
+	if err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok || os.IsNotExist(err) {
+			c.warnFunction(fmt.Sprintf("no %s.yaml found in %s, continuing without it", baseName, dirPath))
+			return
+		}
+		c.failFunction(fmt.Errorf("fatal error reading %s.yaml: %s", baseName, err))
+	}
+}
+
+func (c *Config) trackWatchedFile(path string) {
+	for _, existing := range c.watchedFiles {
+		if existing == path {
+			return
+		}
+	}
+	c.watchedFiles = append(c.watchedFiles, path)
+}
@@ -0,0 +1,122 @@
+package auconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	auconfigapi "github.com/StephanHCB/go-autumn-config-api"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func newProfileTestConfig(t *testing.T, configDir string) *Config {
+	t.Helper()
+
+	items := []auconfigapi.ConfigItem{
+		ConfigItemProfile,
+		{Key: "some-value", Default: "default", Validate: auconfigapi.ConfigNeedsNoValidation},
+	}
+
+	c := NewConfig(pflag.NewFlagSet("test", pflag.ContinueOnError), viper.New())
+	c.configItems = items
+	c.failFunction = func(err error) { t.Fatalf("unexpected fail: %s", err) }
+	c.warnFunction = func(message string) { t.Log(message) }
+
+	c.initializeFlags(configDir, "")
+	return c
+}
+
+func writeProfileFile(t *testing.T, dir string, name string, contents string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+		t.Fatalf("could not write %s: %s", name, err)
+	}
+}
+
+// TestProfileOverridesBaseConfig confirms an active profile's config-<profile>.yaml overrides
+// the base config.yaml.
+func TestProfileOverridesBaseConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeProfileFile(t, dir, "config.yaml", "profiles:\n  - dev\nsome-value: base\n")
+	writeProfileFile(t, dir, "config-dev.yaml", "some-value: dev-value\n")
+
+	c := newProfileTestConfig(t, dir)
+	if err := c.FlagSet.Parse(nil); err != nil {
+		t.Fatalf("could not parse flags: %s", err)
+	}
+	c.setupDefaults()
+	c.setupEnv()
+	c.setupFlags()
+	c.performLoad()
+
+	if got := c.Get("some-value"); got != "dev-value" {
+		t.Errorf("expected profile file to override base config, got %v", got)
+	}
+}
+
+// TestProfileLosesToEnv confirms that env vars still take precedence over a profile file,
+// matching the precedence of the base config file.
+func TestProfileLosesToEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeProfileFile(t, dir, "config.yaml", "profiles:\n  - dev\nsome-value: base\n")
+	writeProfileFile(t, dir, "config-dev.yaml", "some-value: dev-value\n")
+
+	os.Setenv("CONFIG_SOME_VALUE", "from-env")
+	defer os.Unsetenv("CONFIG_SOME_VALUE")
+
+	c := newProfileTestConfig(t, dir)
+	if err := c.FlagSet.Parse(nil); err != nil {
+		t.Fatalf("could not parse flags: %s", err)
+	}
+	c.setupDefaults()
+	c.setupEnv()
+	c.setupFlags()
+	c.performLoad()
+
+	if got := c.Get("some-value"); got != "from-env" {
+		t.Errorf("expected env var to override profile file, got %v", got)
+	}
+}
+
+// TestMultipleProfilesLastOneWins confirms that when several profiles are active, a later
+// profile in the list overrides an earlier one.
+func TestMultipleProfilesLastOneWins(t *testing.T) {
+	dir := t.TempDir()
+	writeProfileFile(t, dir, "config.yaml", "profiles:\n  - dev\n  - local\nsome-value: base\n")
+	writeProfileFile(t, dir, "config-dev.yaml", "some-value: dev-value\n")
+	writeProfileFile(t, dir, "config-local.yaml", "some-value: local-value\n")
+
+	c := newProfileTestConfig(t, dir)
+	if err := c.FlagSet.Parse(nil); err != nil {
+		t.Fatalf("could not parse flags: %s", err)
+	}
+	c.setupDefaults()
+	c.setupEnv()
+	c.setupFlags()
+	c.performLoad()
+
+	if got := c.Get("some-value"); got != "local-value" {
+		t.Errorf("expected the last listed profile to win, got %v", got)
+	}
+}
+
+// TestMissingProfileFileIsOnlyAWarning confirms an active profile with no corresponding file
+// does not fail loading.
+func TestMissingProfileFileIsOnlyAWarning(t *testing.T) {
+	dir := t.TempDir()
+	writeProfileFile(t, dir, "config.yaml", "profiles:\n  - nonexistent\nsome-value: base\n")
+
+	c := newProfileTestConfig(t, dir)
+	if err := c.FlagSet.Parse(nil); err != nil {
+		t.Fatalf("could not parse flags: %s", err)
+	}
+	c.setupDefaults()
+	c.setupEnv()
+	c.setupFlags()
+	c.performLoad()
+
+	if got := c.Get("some-value"); got != "base" {
+		t.Errorf("expected base config value to remain, got %v", got)
+	}
+}
@@ -0,0 +1,181 @@
+package auconfig
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	auconfigapi "github.com/StephanHCB/go-autumn-config-api"
+	"github.com/spf13/viper"
+)
+
+// RemoteConfigItem decorates a configItem with the path under which its value lives in a
+// remote backend (an etcd/Consul key, or a Vault secret path). Leave RemotePath empty to rely
+// on the provider's own merged document tree instead of fetching this item individually.
+type RemoteConfigItem struct {
+	auconfigapi.ConfigItem
+	RemotePath string
+}
+
+// RemoteProvider registers a remote key/value backend with viper, e.g. etcd v3 or Consul KV.
+// Implement this interface to plug in a custom backend, such as AWS SSM Parameter Store,
+// without this package having to depend on viper's crypt/remote machinery directly.
+type RemoteProvider interface {
+	// Register adds itself to v, typically via v.AddRemoteProvider or v.AddSecureRemoteProvider.
+	Register(v *viper.Viper) error
+}
+
+// RemoteItemProvider is additionally implemented by providers that can also fetch a single
+// value by path, such as HashiCorp Vault. When a RemoteConfigItem sets RemotePath, it is
+// fetched individually instead of relying on the provider's merged document tree.
+type RemoteItemProvider interface {
+	RemoteProvider
+	FetchItem(path string) (interface{}, error)
+}
+
+// SetupWithRemote works like Setup()+Load() combined: it registers the given remote providers,
+// loads each item's value from them, and validates the result, all in one call - you do not
+// need to call Load() afterwards. Loader precedence is flag > env > remote > local file >
+// default: remote values are merged in on top of the local config file, but still lose out to
+// anything set via an env var or a command line flag.
+func (c *Config) SetupWithRemote(items []RemoteConfigItem, failFunc auconfigapi.ConfigFailFunc, warnFunc auconfigapi.ConfigWarnFunc, providers []RemoteProvider) {
+	c.remoteConfigItems = items
+	c.remoteProviders = providers
+
+	plainItems := make([]auconfigapi.ConfigItem, 0, len(items))
+	for _, item := range items {
+		plainItems = append(plainItems, item.ConfigItem)
+	}
+	c.configItems = plainItems
+	c.failFunction = failFunc
+	c.warnFunction = warnFunc
+
+	c.initializeFlags("", "")
+	if err := c.FlagSet.Parse(os.Args[1:]); err != nil {
+		c.failFunction(err)
+	}
+
+	c.setupDefaults()
+	c.setupEnv()
+	c.performLoad()
+
+	c.loadRemote()
+
+	c.setupFlags()
+	c.validate()
+}
+
+// SetupWithRemote sets up the default Config instance with remote providers. See
+// Config.SetupWithRemote.
+func SetupWithRemote(items []RemoteConfigItem, failFunc auconfigapi.ConfigFailFunc, warnFunc auconfigapi.ConfigWarnFunc, providers []RemoteProvider) {
+	defaultConfig.SetupWithRemote(items, failFunc, warnFunc, providers)
+}
+
+// loadRemote reads every registered remote provider's document tree into an isolated viper
+// instance, then merges it on top of whatever performLoad already read from the local file, so
+// remote values win over the file but remain below env vars and flags, which are applied last.
+func (c *Config) loadRemote() {
+	if len(c.remoteProviders) == 0 {
+		return
+	}
+
+	c.remoteViperInstance = viper.New()
+	for _, provider := range c.remoteProviders {
+		if err := provider.Register(c.remoteViperInstance); err != nil {
+			c.failFunction(fmt.Errorf("fatal error could not register remote config provider: %s", err))
+		}
+	}
+
+	if err := c.remoteViperInstance.ReadRemoteConfig(); err != nil {
+		c.warnFunction(fmt.Sprintf("could not read remote config, continuing with local file/env/flags: %s", err))
+	}
+
+	c.reapplyRemote()
+}
+
+// reapplyRemote re-merges the already-fetched remote settings (and any individually-fetched
+// items) on top of whatever is currently in c.Viper's config layer.
+//
+// This must be called again after every local file reload: loadConfigFile's ReadInConfig call
+// for the primary config file wholesale-replaces viper's config layer rather than merging into
+// it, which would otherwise silently wipe out remote-sourced values on the next local file
+// change picked up by Watch(). It is a no-op if no remote providers were registered.
+func (c *Config) reapplyRemote() {
+	if c.remoteViperInstance == nil {
+		return
+	}
+
+	if err := c.Viper.MergeConfigMap(c.remoteViperInstance.AllSettings()); err != nil {
+		c.warnFunction(fmt.Sprintf("could not merge remote config: %s", err))
+	}
+
+	c.fetchIndividualRemoteItems()
+}
+
+func (c *Config) fetchIndividualRemoteItems() {
+	for _, item := range c.remoteConfigItems {
+		if item.RemotePath == "" {
+			continue
+		}
+		for _, provider := range c.remoteProviders {
+			itemProvider, ok := provider.(RemoteItemProvider)
+			if !ok {
+				continue
+			}
+			value, err := itemProvider.FetchItem(item.RemotePath)
+			if err != nil {
+				c.warnFunction(fmt.Sprintf("could not fetch remote value for %s from %s: %s", item.Key, item.RemotePath, err))
+				continue
+			}
+			if err := c.Viper.MergeConfigMap(map[string]interface{}{item.Key: value}); err != nil {
+				c.warnFunction(fmt.Sprintf("could not merge remote value for %s: %s", item.Key, err))
+			}
+		}
+	}
+}
+
+// WatchRemote starts a background goroutine that polls every remote provider registered via
+// SetupWithRemote at the given interval. Changes are merged in and validated the same way
+// Watch() validates local file changes, rolling back to the previous values on failure.
+//
+// Call this after SetupWithRemote(). It is a no-op if no remote providers were registered.
+func (c *Config) WatchRemote(pollInterval time.Duration) {
+	if c.remoteViperInstance == nil {
+		return
+	}
+
+	go func() {
+		for {
+			time.Sleep(pollInterval)
+
+			if err := c.remoteViperInstance.WatchRemoteConfig(); err != nil {
+				c.warnFunction(fmt.Sprintf("could not poll remote config: %s", err))
+				continue
+			}
+			c.reloadFromRemote()
+		}
+	}()
+}
+
+// WatchRemote polls the default Config instance's remote providers. See Config.WatchRemote.
+func WatchRemote(pollInterval time.Duration) {
+	defaultConfig.WatchRemote(pollInterval)
+}
+
+// reloadFromRemote holds c.mu for its entire duration - see reloadAndNotify for why.
+func (c *Config) reloadFromRemote() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previousValues := c.snapshotValues()
+
+	c.reapplyRemote()
+
+	if err := c.validateAll(); err != nil {
+		c.warnFunction(fmt.Sprintf("remote config reload failed validation, rolling back: %s", err))
+		c.restoreValues(previousValues)
+		return
+	}
+
+	c.notifyListeners(previousValues)
+}
@@ -0,0 +1,154 @@
+package auconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	auconfigapi "github.com/StephanHCB/go-autumn-config-api"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// fakeRemoteProvider stands in for a real etcd/Consul/Vault backend in tests: Register is a
+// no-op, since these tests drive the remote document tree directly instead of going through
+// viper's real remote-config machinery (which requires a compiled-in backend).
+type fakeRemoteProvider struct {
+	items map[string]interface{}
+}
+
+func (f *fakeRemoteProvider) Register(v *viper.Viper) error {
+	return nil
+}
+
+func (f *fakeRemoteProvider) FetchItem(path string) (interface{}, error) {
+	value, ok := f.items[path]
+	if !ok {
+		return nil, fmt.Errorf("no value at path %s", path)
+	}
+	return value, nil
+}
+
+func newRemoteTestConfig(t *testing.T, items []RemoteConfigItem, configDir string) *Config {
+	t.Helper()
+
+	plainItems := make([]auconfigapi.ConfigItem, 0, len(items))
+	for _, item := range items {
+		plainItems = append(plainItems, item.ConfigItem)
+	}
+
+	c := NewConfig(pflag.NewFlagSet("test", pflag.ContinueOnError), viper.New())
+	c.remoteConfigItems = items
+	c.configItems = plainItems
+	c.failFunction = func(err error) { t.Fatalf("unexpected fail: %s", err) }
+	c.warnFunction = func(message string) { t.Log(message) }
+
+	c.initializeFlags(configDir, "")
+	return c
+}
+
+// TestReapplyRemoteOverridesFileButLosesToEnv confirms remote values merge in on top of the
+// local config file, but the merge target (viper's config layer) still loses out to env vars,
+// matching the documented flag > env > remote > file > default precedence.
+func TestReapplyRemoteOverridesFileButLosesToEnv(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("some-value: file-value\n"), 0644); err != nil {
+		t.Fatalf("could not write config.yaml: %s", err)
+	}
+
+	items := []RemoteConfigItem{
+		{ConfigItem: auconfigapi.ConfigItem{Key: "some-value", Default: "default", Validate: auconfigapi.ConfigNeedsNoValidation}},
+	}
+	c := newRemoteTestConfig(t, items, dir)
+	if err := c.FlagSet.Parse(nil); err != nil {
+		t.Fatalf("could not parse flags: %s", err)
+	}
+	c.setupDefaults()
+	c.setupEnv()
+	c.setupFlags()
+	c.performLoad()
+
+	c.remoteViperInstance = viper.New()
+	if err := c.remoteViperInstance.MergeConfigMap(map[string]interface{}{"some-value": "remote-value"}); err != nil {
+		t.Fatalf("could not seed remote instance: %s", err)
+	}
+	c.reapplyRemote()
+
+	if got := c.Get("some-value"); got != "remote-value" {
+		t.Errorf("expected remote value to override file value, got %v", got)
+	}
+
+	os.Setenv("CONFIG_SOME_VALUE", "env-value")
+	defer os.Unsetenv("CONFIG_SOME_VALUE")
+	c.setupEnv()
+
+	if got := c.Get("some-value"); got != "env-value" {
+		t.Errorf("expected env var to still win over a remote value, got %v", got)
+	}
+}
+
+// TestFetchIndividualRemoteItemsOverridesDocumentTree confirms a RemoteConfigItem with a
+// RemotePath set is fetched individually and overrides whatever the provider's merged document
+// tree already contributed for that same key.
+func TestFetchIndividualRemoteItemsOverridesDocumentTree(t *testing.T) {
+	provider := &fakeRemoteProvider{items: map[string]interface{}{
+		"/individual/some-value": "individual-value",
+	}}
+
+	items := []RemoteConfigItem{
+		{
+			ConfigItem: auconfigapi.ConfigItem{Key: "some-value", Default: "default", Validate: auconfigapi.ConfigNeedsNoValidation},
+			RemotePath: "/individual/some-value",
+		},
+	}
+	c := newRemoteTestConfig(t, items, "")
+	c.remoteProviders = []RemoteProvider{provider}
+	if err := c.FlagSet.Parse(nil); err != nil {
+		t.Fatalf("could not parse flags: %s", err)
+	}
+	c.setupDefaults()
+	c.setupEnv()
+	c.setupFlags()
+
+	c.remoteViperInstance = viper.New()
+	if err := c.remoteViperInstance.MergeConfigMap(map[string]interface{}{"some-value": "document-tree-value"}); err != nil {
+		t.Fatalf("could not seed remote instance: %s", err)
+	}
+	c.reapplyRemote()
+
+	if got := c.Get("some-value"); got != "individual-value" {
+		t.Errorf("expected individually fetched value to win, got %v", got)
+	}
+}
+
+// TestReloadFromRemoteRollsBackOnInvalidValue confirms a poll that produces an invalid remote
+// value is rolled back, the same way Watch() rolls back an invalid local file reload.
+func TestReloadFromRemoteRollsBackOnInvalidValue(t *testing.T) {
+	items := []RemoteConfigItem{
+		{ConfigItem: auconfigapi.ConfigItem{Key: "some-value", Default: "good", Validate: auconfigapi.ConfigNeedsNoValidation}},
+	}
+	c := newRemoteTestConfig(t, items, "")
+	c.configItems[0].Validate = func(key string) error {
+		if c.Viper.GetString(key) == "bad" {
+			return fmt.Errorf("value must not be bad")
+		}
+		return nil
+	}
+	if err := c.FlagSet.Parse(nil); err != nil {
+		t.Fatalf("could not parse flags: %s", err)
+	}
+	c.setupDefaults()
+	c.setupEnv()
+	c.setupFlags()
+
+	c.remoteViperInstance = viper.New()
+	if err := c.remoteViperInstance.MergeConfigMap(map[string]interface{}{"some-value": "bad"}); err != nil {
+		t.Fatalf("could not seed remote instance: %s", err)
+	}
+	c.reloadFromRemote()
+
+	if got := c.Get("some-value"); got != "good" {
+		t.Errorf("expected rollback to keep the previous good value, got %v", got)
+	}
+}
@@ -4,144 +4,192 @@ import (
 	"fmt"
 	"github.com/StephanHCB/go-autumn-config-api"
 	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
 	"log"
+	"net"
+	"os"
 	"regexp"
+	"strings"
+	"time"
 )
 
-var configPath string
-var secretsPath string
+// FlagRegistrar lets you plug in a custom pflag.Value implementation for a configItem whose
+// Default type initializeFlags does not already know how to turn into a flag.
+type FlagRegistrar func(fs *pflag.FlagSet, name string, desc string)
 
-var failFunction auconfigapi.ConfigFailFunc = fail
-var warnFunction auconfigapi.ConfigWarnFunc = warn
-
-var configItems []auconfigapi.ConfigItem
+// RegisterFlagType registers a FlagRegistrar for the configItem identified by key, to be used
+// instead of the built-in type switch in initializeFlags. Call this before Setup().
+func (c *Config) RegisterFlagType(key string, registrar FlagRegistrar) {
+	c.flagRegistrars[key] = registrar
+}
 
-var configItemKeysWithNoFlags = map[string]bool{}
+// RegisterFlagType registers a FlagRegistrar on the default Config instance.
+func RegisterFlagType(key string, registrar FlagRegistrar) {
+	defaultConfig.RegisterFlagType(key, registrar)
+}
 
 // initialize configuration with full setup - you need to call this from your code
+func (c *Config) Setup(items []auconfigapi.ConfigItem, failFunc auconfigapi.ConfigFailFunc, warnFunc auconfigapi.ConfigWarnFunc) {
+	c.SetupWithOverriddenConfigPath(items, failFunc, warnFunc, "", "")
+}
+
+// Setup initializes the default Config instance. See Config.Setup.
 func Setup(items []auconfigapi.ConfigItem, failFunc auconfigapi.ConfigFailFunc, warnFunc auconfigapi.ConfigWarnFunc) {
-	SetupWithOverriddenConfigPath(items, failFunc, warnFunc, "", "")
+	defaultConfig.Setup(items, failFunc, warnFunc)
 }
 
 // load any configuration files - you need to call this from your code after calling Setup()
+func (c *Config) Load() {
+	c.performLoad()
+	c.validate()
+}
+
+// Load loads the configuration files for the default Config instance. See Config.Load.
 func Load() {
-	performLoad()
-	validate()
+	defaultConfig.Load()
 }
 
 // use this for unit tests.
 //
 // This just sets all configuration settings to their default values. No need to call Load() after this.
-func SetupDefaultsOnly(items []auconfigapi.ConfigItem, failFunc auconfigapi.ConfigFailFunc, warnFunc auconfigapi.ConfigWarnFunc) {
-	configItems = items
-	failFunction = failFunc
-	warnFunction = warnFunc
+func (c *Config) SetupDefaultsOnly(items []auconfigapi.ConfigItem, failFunc auconfigapi.ConfigFailFunc, warnFunc auconfigapi.ConfigWarnFunc) {
+	c.configItems = items
+	c.failFunction = failFunc
+	c.warnFunction = warnFunc
 
-	setupDefaults()
+	c.setupDefaults()
+}
+
+// SetupDefaultsOnly sets up the default Config instance. See Config.SetupDefaultsOnly.
+func SetupDefaultsOnly(items []auconfigapi.ConfigItem, failFunc auconfigapi.ConfigFailFunc, warnFunc auconfigapi.ConfigWarnFunc) {
+	defaultConfig.SetupDefaultsOnly(items, failFunc, warnFunc)
 }
 
 // use this for integration tests instead of Setup().
 //
 // This allows you to specify a default path for both config and secrets files, avoiding the need for command line parameters in integration tests.
 // You still need to call Load(). Set defaultSecretsPath to "" to disable loading it.
-func SetupWithOverriddenConfigPath(items []auconfigapi.ConfigItem, failFunc auconfigapi.ConfigFailFunc, warnFunc auconfigapi.ConfigWarnFunc, defaultConfigPath string, defaultSecretsPath string) {
-	configItems = items
-	failFunction = failFunc
-	warnFunction = warnFunc
+func (c *Config) SetupWithOverriddenConfigPath(items []auconfigapi.ConfigItem, failFunc auconfigapi.ConfigFailFunc, warnFunc auconfigapi.ConfigWarnFunc, defaultConfigPath string, defaultSecretsPath string) {
+	c.configItems = items
+	c.failFunction = failFunc
+	c.warnFunction = warnFunc
+
+	c.initializeFlags(defaultConfigPath, defaultSecretsPath)
+	if err := c.FlagSet.Parse(os.Args[1:]); err != nil {
+		c.failFunction(err)
+	}
 
-	initializeFlags(defaultConfigPath, defaultSecretsPath)
-	pflag.Parse()
+	c.setupDefaults()
+	c.setupEnv()
+	c.setupFlags()
+}
 
-	setupDefaults()
-	setupEnv()
-	setupFlags()
+// SetupWithOverriddenConfigPath sets up the default Config instance. See
+// Config.SetupWithOverriddenConfigPath.
+func SetupWithOverriddenConfigPath(items []auconfigapi.ConfigItem, failFunc auconfigapi.ConfigFailFunc, warnFunc auconfigapi.ConfigWarnFunc, defaultConfigPath string, defaultSecretsPath string) {
+	defaultConfig.SetupWithOverriddenConfigPath(items, failFunc, warnFunc, defaultConfigPath, defaultSecretsPath)
 }
 
-func initializeFlags(defaultConfigPath string, defaultSecretsPath string) {
-	pflag.StringVar(&configPath, "config-path", defaultConfigPath, "config file path without file name")
-	pflag.StringVar(&secretsPath, "secrets-path", defaultSecretsPath, "secrets file path without file name")
+func (c *Config) initializeFlags(defaultConfigPath string, defaultSecretsPath string) {
+	c.FlagSet.StringVar(&c.configPath, "config-path", defaultConfigPath, "config file path without file name")
+	c.FlagSet.StringVar(&c.secretsPath, "secrets-path", defaultSecretsPath, "secrets file path without file name")
 
-	for _, item := range configItems {
+	for _, item := range c.configItems {
 		flagname := item.FlagName
 		if flagname == "" {
 			flagname = item.Key
 		}
 
+		if registrar, ok := c.flagRegistrars[item.Key]; ok {
+			registrar(c.FlagSet, flagname, item.Description)
+			continue
+		}
+
 		// must set null default values here, or else this value will overwrite config values from config file
 		if _, ok := item.Default.(string); ok {
-			pflag.String(flagname, "", item.Description)
+			c.FlagSet.String(flagname, "", item.Description)
 		} else if _, ok := item.Default.(int); ok {
-			pflag.Int(flagname, 0, item.Description)
+			c.FlagSet.Int(flagname, 0, item.Description)
 		} else if _, ok := item.Default.(int8); ok {
-			pflag.Int8(flagname, 0, item.Description)
+			c.FlagSet.Int8(flagname, 0, item.Description)
 		} else if _, ok := item.Default.(int16); ok {
-			pflag.Int16(flagname, 0, item.Description)
+			c.FlagSet.Int16(flagname, 0, item.Description)
 		} else if _, ok := item.Default.(int32); ok {
-			pflag.Int32(flagname, 0, item.Description)
+			c.FlagSet.Int32(flagname, 0, item.Description)
 		} else if _, ok := item.Default.(uint); ok {
-			pflag.Uint(flagname, 0, item.Description)
+			c.FlagSet.Uint(flagname, 0, item.Description)
 		} else if _, ok := item.Default.(uint8); ok {
-			pflag.Uint8(flagname, 0, item.Description)
+			c.FlagSet.Uint8(flagname, 0, item.Description)
 		} else if _, ok := item.Default.(uint16); ok {
-			pflag.Uint16(flagname, 0, item.Description)
+			c.FlagSet.Uint16(flagname, 0, item.Description)
 		} else if _, ok := item.Default.(uint32); ok {
-			pflag.Uint32(flagname, 0, item.Description)
+			c.FlagSet.Uint32(flagname, 0, item.Description)
 		} else if _, ok := item.Default.(bool); ok {
-			pflag.Bool(flagname, false, item.Description)
+			c.FlagSet.Bool(flagname, false, item.Description)
 		} else if _, ok := item.Default.([]string); ok {
-			pflag.StringSlice(flagname, []string{}, item.Description)
+			c.FlagSet.StringSlice(flagname, []string{}, item.Description)
+		} else if _, ok := item.Default.(time.Duration); ok {
+			c.FlagSet.Duration(flagname, 0, item.Description)
+		} else if _, ok := item.Default.(float32); ok {
+			c.FlagSet.Float32(flagname, 0, item.Description)
+		} else if _, ok := item.Default.(float64); ok {
+			c.FlagSet.Float64(flagname, 0, item.Description)
+		} else if _, ok := item.Default.(net.IP); ok {
+			c.FlagSet.IP(flagname, nil, item.Description)
+		} else if _, ok := item.Default.([]int); ok {
+			c.FlagSet.IntSlice(flagname, []int{}, item.Description)
+		} else if _, ok := item.Default.(map[string]string); ok {
+			c.FlagSet.StringToString(flagname, map[string]string{}, item.Description)
 		} else {
-			configItemKeysWithNoFlags[item.Key] = true
-			warnFunction(fmt.Sprintf("unsupported data type for config item %v, cannot initialize command line argument, skipping this key", item.Key))
+			c.configItemKeysWithNoFlags[item.Key] = true
+			c.warnFunction(fmt.Sprintf("unsupported data type for config item %v, cannot initialize command line argument, skipping this key", item.Key))
 		}
 	}
 }
 
-func setupFlags() {
-	for _, item := range configItems {
-		if _, ok := configItemKeysWithNoFlags[item.Key]; !ok {
+func (c *Config) setupFlags() {
+	for _, item := range c.configItems {
+		if _, ok := c.configItemKeysWithNoFlags[item.Key]; !ok {
 			flagname := item.FlagName
 			if flagname == "" {
 				flagname = item.Key
 			}
 
-			bindFlag(item.Key, flagname)
+			c.bindFlag(item.Key, flagname)
 		}
 	}
 }
 
-func bindFlag(key string, flagname string) {
-	err := viper.BindPFlag(key, pflag.Lookup(flagname))
+func (c *Config) bindFlag(key string, flagname string) {
+	err := c.Viper.BindPFlag(key, c.FlagSet.Lookup(flagname))
 	if err != nil {
-		failFunction(fmt.Errorf("Fatal error could not bind configuration flag %s: %s\n", key, err))
+		c.failFunction(fmt.Errorf("Fatal error could not bind configuration flag %s: %s\n", key, err))
 	}
 }
 
-func setupDefaults() {
-	for _, item := range configItems {
-		viper.SetDefault(item.Key, item.Default)
+func (c *Config) setupDefaults() {
+	for _, item := range c.configItems {
+		c.Viper.SetDefault(item.Key, item.Default)
 	}
 }
 
-func setupEnv() {
+func (c *Config) setupEnv() {
 	re := regexp.MustCompile(`[^a-z0-9]`)
-	for _, item := range configItems {
+	for _, item := range c.configItems {
 		// simply fill in EnvName if unset
 		if item.EnvName == "" {
-			item.EnvName = "CONFIG_" + re.ReplaceAllString(item.Key, "_")
+			item.EnvName = "CONFIG_" + strings.ToUpper(re.ReplaceAllString(item.Key, "_"))
 		}
 
 		// the only error that can occur is when the Key is empty
-		_ = viper.BindEnv(item.Key, item.EnvName)
+		_ = c.Viper.BindEnv(item.Key, item.EnvName)
 	}
 }
 
-func validate() {
-	for _, item := range configItems {
+func (c *Config) validate() {
+	for _, item := range c.configItems {
 		err := item.Validate(item.Key)
 		if err != nil {
-			fail(err)
+			c.failFunction(err)
 		}
 	}
 }
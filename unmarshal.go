@@ -0,0 +1,75 @@
+package auconfig
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// DecoderConfigOption lets callers tweak the mapstructure.DecoderConfig used by Unmarshal and
+// UnmarshalKey, e.g. to register additional DecodeHookFuncs for custom types.
+type DecoderConfigOption func(*mapstructure.DecoderConfig)
+
+// Unmarshal populates target, which must be a pointer to a struct using `mapstructure` tags,
+// with the fully resolved value of every configured item - the same value Get() would return.
+//
+// viper.Unmarshal by itself ignores env vars and flags unless a config file was actually
+// loaded, because it only considers keys viper already knows about from defaults or the file.
+// Rather than working around this by pushing values into viper's override layer (which would
+// permanently pin them above every later file, env or flag value, defeating Watch()), this
+// decodes c.Viper.AllSettings() directly with mapstructure: AllSettings already resolves every
+// known key through Get(), so it reflects the same flag > env > file > default precedence
+// without mutating viper's state. Nested structs, time.Duration, []string and
+// map[string]string fields are all supported out of the box.
+func (c *Config) Unmarshal(target interface{}, opts ...DecoderConfigOption) error {
+	c.mu.RLock()
+	settings := c.Viper.AllSettings()
+	c.mu.RUnlock()
+
+	if err := decode(settings, target, opts...); err != nil {
+		return fmt.Errorf("failed to unmarshal config into target struct: %s", err)
+	}
+	return nil
+}
+
+// Unmarshal decodes the default Config instance's configuration. See Config.Unmarshal.
+func Unmarshal(target interface{}, opts ...DecoderConfigOption) error {
+	return defaultConfig.Unmarshal(target, opts...)
+}
+
+// UnmarshalKey works like Unmarshal, but only decodes the single configItem identified by key.
+func (c *Config) UnmarshalKey(key string, target interface{}, opts ...DecoderConfigOption) error {
+	c.mu.RLock()
+	value := c.Viper.Get(key)
+	c.mu.RUnlock()
+
+	if err := decode(value, target, opts...); err != nil {
+		return fmt.Errorf("failed to unmarshal config key %s into target struct: %s", key, err)
+	}
+	return nil
+}
+
+// UnmarshalKey decodes a single key from the default Config instance. See Config.UnmarshalKey.
+func UnmarshalKey(key string, target interface{}, opts ...DecoderConfigOption) error {
+	return defaultConfig.UnmarshalKey(key, target, opts...)
+}
+
+func decode(source interface{}, target interface{}, opts ...DecoderConfigOption) error {
+	decoderConfig := &mapstructure.DecoderConfig{
+		Result:           target,
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	}
+	for _, opt := range opts {
+		opt(decoderConfig)
+	}
+
+	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(source)
+}
@@ -0,0 +1,98 @@
+package auconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	auconfigapi "github.com/StephanHCB/go-autumn-config-api"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+type unmarshalTarget struct {
+	SomeString string `mapstructure:"some-string"`
+	SomeNumber int    `mapstructure:"some-number"`
+}
+
+func newUnmarshalTestConfig(t *testing.T, configDir string) *Config {
+	t.Helper()
+
+	items := []auconfigapi.ConfigItem{
+		{Key: "some-string", Default: "default-string", Validate: auconfigapi.ConfigNeedsNoValidation},
+		{Key: "some-number", Default: 1, Validate: auconfigapi.ConfigNeedsNoValidation},
+	}
+
+	c := NewConfig(pflag.NewFlagSet("test", pflag.ContinueOnError), viper.New())
+	c.configItems = items
+	c.failFunction = func(err error) { t.Fatalf("unexpected fail: %s", err) }
+	c.warnFunction = func(message string) { t.Log(message) }
+
+	c.initializeFlags(configDir, "")
+	return c
+}
+
+// TestUnmarshalIncludesEnvAndFlagOverlay confirms that Unmarshal and UnmarshalKey reflect the
+// full flag > env > file > default precedence, not just what viper happened to load from the
+// config file. viper.Unmarshal by itself ignores env vars and flags for keys it does not
+// already know about from defaults or a config file, which is why decode() is fed
+// c.Viper.AllSettings() instead.
+func TestUnmarshalIncludesEnvAndFlagOverlay(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("some-string: from-file\n"), 0644); err != nil {
+		t.Fatalf("could not write config.yaml: %s", err)
+	}
+
+	os.Setenv("CONFIG_SOME_NUMBER", "2")
+	defer os.Unsetenv("CONFIG_SOME_NUMBER")
+
+	c := newUnmarshalTestConfig(t, dir)
+	if err := c.FlagSet.Parse([]string{"--some-string=from-flag"}); err != nil {
+		t.Fatalf("could not parse flags: %s", err)
+	}
+	c.setupDefaults()
+	c.setupEnv()
+	c.setupFlags()
+	c.performLoad()
+
+	var target unmarshalTarget
+	if err := c.Unmarshal(&target); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if target.SomeString != "from-flag" {
+		t.Errorf("expected some-string to come from the flag, got %q", target.SomeString)
+	}
+	if target.SomeNumber != 2 {
+		t.Errorf("expected some-number to come from the env var, got %d", target.SomeNumber)
+	}
+
+	var number int
+	if err := c.UnmarshalKey("some-number", &number); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if number != 2 {
+		t.Errorf("expected UnmarshalKey(some-number) to come from the env var, got %d", number)
+	}
+}
+
+// TestUnmarshalFallsBackToDefaults confirms Unmarshal still works with nothing set beyond
+// defaults, i.e. it does not require a config file to exist.
+func TestUnmarshalFallsBackToDefaults(t *testing.T) {
+	c := newUnmarshalTestConfig(t, "")
+	if err := c.FlagSet.Parse(nil); err != nil {
+		t.Fatalf("could not parse flags: %s", err)
+	}
+	c.setupDefaults()
+	c.setupEnv()
+	c.setupFlags()
+
+	var target unmarshalTarget
+	if err := c.Unmarshal(&target); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if target.SomeString != "default-string" || target.SomeNumber != 1 {
+		t.Errorf("expected defaults, got %+v", target)
+	}
+}
@@ -0,0 +1,168 @@
+package auconfig
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ConfigChangeFunc is invoked with a configItem's key and its old/new value whenever Watch()
+// (or WatchRemote()) successfully applies a change. Register one with AddChangeListener.
+type ConfigChangeFunc func(key string, oldValue interface{}, newValue interface{})
+
+// AddChangeListener registers a callback to be invoked by Watch() after a successful reload.
+//
+// Pass an empty key to be notified of every change, or a configItem key to only be notified
+// when that particular value changes.
+func (c *Config) AddChangeListener(key string, listener ConfigChangeFunc) {
+	if key == "" {
+		c.globalChangeListeners = append(c.globalChangeListeners, listener)
+		return
+	}
+	c.keyedChangeListeners[key] = append(c.keyedChangeListeners[key], listener)
+}
+
+// AddChangeListener registers a change listener on the default Config instance. See
+// Config.AddChangeListener.
+func AddChangeListener(key string, listener ConfigChangeFunc) {
+	defaultConfig.AddChangeListener(key, listener)
+}
+
+// Watch starts watching the config file, the secrets file, and every profile file performLoad
+// has read or attempted to read, for changes, reloading them on the fly.
+//
+// Call this after Load(). On each change, validation is re-run for every configured item. If
+// validation fails, the previous, known-good values are restored and the change is discarded,
+// so a faulty edit to a config file cannot crash or corrupt a running service. Successfully
+// applied changes are reported to any listener registered via AddChangeListener. If remote
+// providers were registered via SetupWithRemote, their already-fetched values are re-merged in
+// after every local file reload, so Watch() and WatchRemote() can be used together.
+//
+// viper's own WatchConfig only ever tracks the single file last passed to SetConfigFile, which
+// is unusable here since performLoad calls SetConfigFile repeatedly for config.yaml,
+// secrets.yaml and every profile file in turn. Instead, every directory holding a watched file
+// is watched directly, and events are filtered down to the files performLoad tried to load,
+// including ones that did not exist at the time.
+func (c *Config) Watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		c.failFunction(fmt.Errorf("could not start config file watcher: %s", err))
+		return
+	}
+
+	for dir := range watchedDirs(c.watchedFiles) {
+		if err := watcher.Add(dir); err != nil {
+			c.warnFunction(fmt.Sprintf("could not watch config directory %s: %s", dir, err))
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if c.isWatchedFile(event.Name) {
+					c.reloadAndNotify()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				c.warnFunction(fmt.Sprintf("config file watcher error: %s", err))
+			}
+		}
+	}()
+}
+
+// Watch starts watching the default Config instance's files. See Config.Watch.
+func Watch() {
+	defaultConfig.Watch()
+}
+
+func watchedDirs(files []string) map[string]bool {
+	dirs := make(map[string]bool, len(files))
+	for _, file := range files {
+		dirs[filepath.Dir(file)] = true
+	}
+	return dirs
+}
+
+func (c *Config) isWatchedFile(name string) bool {
+	for _, file := range c.watchedFiles {
+		if filepath.Clean(name) == filepath.Clean(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadAndNotify holds c.mu for its entire duration, because performLoad and restoreValues
+// both write to c.Viper while Get/Unmarshal may be reading it concurrently from the running
+// service this reload is meant to protect, not crash.
+func (c *Config) reloadAndNotify() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previousValues := c.snapshotValues()
+
+	c.performLoad()
+	c.reapplyRemote()
+
+	if err := c.validateAll(); err != nil {
+		c.warnFunction(fmt.Sprintf("config reload failed validation, rolling back: %s", err))
+		c.restoreValues(previousValues)
+		return
+	}
+
+	c.notifyListeners(previousValues)
+}
+
+// snapshotValues, restoreValues, validateAll and notifyListeners all assume c.mu is already
+// held by the caller (reloadAndNotify or reloadFromRemote); they are not safe to call on their
+// own.
+func (c *Config) snapshotValues() map[string]interface{} {
+	values := make(map[string]interface{}, len(c.configItems))
+	for _, item := range c.configItems {
+		values[item.Key] = c.Viper.Get(item.Key)
+	}
+	return values
+}
+
+// restoreValues merges the given values back into viper's config layer, the same layer
+// performLoad itself writes to. It deliberately avoids viper.Set, which writes into the
+// override layer sitting above flags and env vars - using it here would permanently pin these
+// keys and make them immune to every later, legitimate file/env/flag change.
+func (c *Config) restoreValues(values map[string]interface{}) {
+	if err := c.Viper.MergeConfigMap(values); err != nil {
+		c.warnFunction(fmt.Sprintf("could not roll back config after failed validation: %s", err))
+	}
+}
+
+func (c *Config) validateAll() error {
+	for _, item := range c.configItems {
+		if err := item.Validate(item.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) notifyListeners(previousValues map[string]interface{}) {
+	for _, item := range c.configItems {
+		oldValue := previousValues[item.Key]
+		newValue := c.Viper.Get(item.Key)
+		if fmt.Sprintf("%v", oldValue) == fmt.Sprintf("%v", newValue) {
+			continue
+		}
+
+		for _, listener := range c.globalChangeListeners {
+			listener(item.Key, oldValue, newValue)
+		}
+		for _, listener := range c.keyedChangeListeners[item.Key] {
+			listener(item.Key, oldValue, newValue)
+		}
+	}
+}
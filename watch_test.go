@@ -0,0 +1,123 @@
+package auconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	auconfigapi "github.com/StephanHCB/go-autumn-config-api"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func newWatchTestConfig(t *testing.T, item auconfigapi.ConfigItem, dir string) *Config {
+	t.Helper()
+
+	c := NewConfig(pflag.NewFlagSet("test", pflag.ContinueOnError), viper.New())
+	c.configItems = []auconfigapi.ConfigItem{item}
+	c.configPath = dir
+	c.failFunction = func(err error) { t.Fatalf("unexpected fail: %s", err) }
+	c.warnFunction = func(message string) { t.Log(message) }
+
+	c.setupDefaults()
+	c.performLoad()
+	return c
+}
+
+func writeWatchedConfigFile(t *testing.T, dir string, contents string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(contents), 0644); err != nil {
+		t.Fatalf("could not write config.yaml: %s", err)
+	}
+}
+
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return condition()
+}
+
+func TestWatchReloadsOnFileChange(t *testing.T) {
+	dir := t.TempDir()
+	writeWatchedConfigFile(t, dir, "some-value: initial\n")
+
+	item := auconfigapi.ConfigItem{Key: "some-value", Default: "default", Validate: auconfigapi.ConfigNeedsNoValidation}
+	c := newWatchTestConfig(t, item, dir)
+	c.Watch()
+
+	writeWatchedConfigFile(t, dir, "some-value: updated\n")
+
+	if !waitFor(t, 2*time.Second, func() bool { return c.Get("some-value") == "updated" }) {
+		t.Fatalf("expected reload to pick up updated value, got %v", c.Get("some-value"))
+	}
+}
+
+// TestWatchRollsBackThenResumes confirms that an invalid reload is rolled back without
+// permanently pinning the key, so a later, legitimate fix to the same file still applies. This
+// guards against restoreValues writing through viper's override layer.
+func TestWatchRollsBackThenResumes(t *testing.T) {
+	dir := t.TempDir()
+	writeWatchedConfigFile(t, dir, "some-value: good\n")
+
+	item := auconfigapi.ConfigItem{Key: "some-value", Default: "default", Validate: auconfigapi.ConfigNeedsNoValidation}
+	c := newWatchTestConfig(t, item, dir)
+	c.configItems[0].Validate = func(key string) error {
+		if c.Viper.GetString(key) == "bad" {
+			return fmt.Errorf("value must not be bad")
+		}
+		return nil
+	}
+	c.Watch()
+
+	writeWatchedConfigFile(t, dir, "some-value: bad\n")
+	if !waitFor(t, 2*time.Second, func() bool { return c.Get("some-value") == "good" }) {
+		t.Fatalf("expected rollback to keep previous value 'good', got %v", c.Get("some-value"))
+	}
+
+	writeWatchedConfigFile(t, dir, "some-value: fixed\n")
+	if !waitFor(t, 2*time.Second, func() bool { return c.Get("some-value") == "fixed" }) {
+		t.Fatalf("expected later valid change to apply, got %v", c.Get("some-value"))
+	}
+}
+
+// TestWatchConcurrentGetDoesNotRace exercises Get() concurrently with Watch()'s reload
+// goroutine. Run with `go test -race` to confirm there is no data race on the shared Viper
+// instance.
+func TestWatchConcurrentGetDoesNotRace(t *testing.T) {
+	dir := t.TempDir()
+	writeWatchedConfigFile(t, dir, "some-value: initial\n")
+
+	item := auconfigapi.ConfigItem{Key: "some-value", Default: "default", Validate: auconfigapi.ConfigNeedsNoValidation}
+	c := newWatchTestConfig(t, item, dir)
+	c.Watch()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				c.Get("some-value")
+			}
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		writeWatchedConfigFile(t, dir, fmt.Sprintf("some-value: v%d\n", i))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(stop)
+	<-done
+}